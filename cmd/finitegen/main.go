@@ -0,0 +1,52 @@
+// Command finitegen reads a frozen model.MetaModel, marshaled to JSON via
+// MetaModel.ToAny, and writes a formatted Go source file exposing typed
+// bindings for its Cells, Fns and Vars. See model/codegen for the
+// generated shape.
+//
+// finitegen only reads the Any/JSON form. It does not take a Go
+// ModelDeclaration and build/reflect over it directly, since the only
+// place a ModelDeclaration lives is source that already imports
+// model/dsl and can call MetaModel.ToAny itself before invoking
+// finitegen on the result.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"github.com/golang/protobuf/ptypes/any"
+
+	"github.com/stackdump/finite-model/model"
+	"github.com/stackdump/finite-model/model/codegen"
+)
+
+func main() {
+	in := flag.String("in", "", "path to a JSON-encoded model.MetaModel (the Any.Value payload from MetaModel.ToAny)")
+	out := flag.String("out", "", "output path for the generated .go file")
+	pkg := flag.String("pkg", "generated", "package name for the generated file")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		log.Fatal("finitegen: -in and -out are required")
+	}
+
+	raw, err := ioutil.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("finitegen: reading %s: %s", *in, err)
+	}
+
+	m, err := model.FromAny(&any.Any{Value: raw})
+	if err != nil {
+		log.Fatalf("finitegen: decoding %s: %s", *in, err)
+	}
+
+	src, err := codegen.Generate(*pkg, m)
+	if err != nil {
+		log.Fatalf("finitegen: %s", err)
+	}
+
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("finitegen: writing %s: %s", *out, err)
+	}
+}
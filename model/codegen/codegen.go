@@ -0,0 +1,216 @@
+// Package codegen emits a strongly-typed Go client for a frozen
+// model.MetaModel, in the spirit of an IDL-to-struct generator: every
+// Cell/Fn label becomes an exported constant, every transition becomes a
+// method that wraps the guard-aware dsl.Model.Transform, and every
+// late-bound Var becomes a typed setter. Consumers of the generated
+// package no longer reference cells/transitions by string, so a typo
+// that would otherwise surface as a runtime assertOK panic is instead a
+// compile error.
+//
+// Generated transition methods take no context.Context: nothing else in
+// this module threads one, and Transform never blocks or does I/O, so
+// there is nothing for it to cancel.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/stackdump/finite-model/model"
+)
+
+// varBinder describes one exported Client setter generated from a
+// model.VarMap: SetCapacityP0, SetInitialP0, SetWeightINC0P0, etc.
+type varBinder struct {
+	Method string
+	Call   string // e.g. `c.m.Var().Capacity(Cell_P0)`
+}
+
+type templateData struct {
+	Package     string
+	Schema      string
+	Places      []identPair
+	Transitions []identPair
+	Binders     []varBinder
+}
+
+type identPair struct {
+	Ident  string // package-level Action_X constant
+	Method string // Client method name for a transition, e.g. INC0
+	Label  string
+}
+
+// Generate walks m and returns formatted Go source for a package named
+// pkgName exposing typed bindings for every Cell, Fn and Var it declares.
+// m is frozen as a side effect if it is not already.
+func Generate(pkgName string, m *model.MetaModel) ([]byte, error) {
+	if !m.IsFrozen() {
+		m.Freeze()
+	}
+
+	data := templateData{
+		Package: pkgName,
+		Schema:  m.Schema,
+	}
+
+	placeLabels := make([]string, 0, len(m.Places))
+	for label := range m.Places {
+		placeLabels = append(placeLabels, label)
+	}
+	sort.Strings(placeLabels)
+	for _, label := range placeLabels {
+		data.Places = append(data.Places, identPair{Ident: toIdent("Cell", label), Label: label})
+	}
+
+	txLabels := make([]string, 0, len(m.Transitions))
+	for action := range m.Transitions {
+		txLabels = append(txLabels, string(action))
+	}
+	sort.Strings(txLabels)
+	for _, label := range txLabels {
+		data.Transitions = append(data.Transitions, identPair{Ident: toIdent("Action", label), Method: toIdent("", label), Label: label})
+	}
+
+	// ref renders a Weight endpoint as the generated constant for it
+	ref := func(label string) string {
+		if _, ok := m.Places[label]; ok {
+			return toIdent("Cell", label)
+		}
+		return "string(" + toIdent("Action", label) + ")"
+	}
+
+	for _, v := range m.GetVars() {
+		switch v.Type() {
+		case model.CapacityVar:
+			data.Binders = append(data.Binders, varBinder{
+				Method: "SetCapacity" + toIdent("", v.Ref.Source),
+				Call:   fmt.Sprintf("c.m.Var().Capacity(%s)", toIdent("Cell", v.Ref.Source)),
+			})
+		case model.InitialVar:
+			data.Binders = append(data.Binders, varBinder{
+				Method: "SetInitial" + toIdent("", v.Ref.Target),
+				Call:   fmt.Sprintf("c.m.Var().Initial(%s)", toIdent("Cell", v.Ref.Target)),
+			})
+		case model.WeightVar:
+			data.Binders = append(data.Binders, varBinder{
+				Method: "SetWeight" + toIdent("", v.Ref.Source) + toIdent("", v.Ref.Target),
+				Call:   fmt.Sprintf("c.m.Var().Weight(%s, %s)", ref(v.Ref.Source), ref(v.Ref.Target)),
+			})
+		case model.GuardVar:
+			data.Binders = append(data.Binders, varBinder{
+				Method: "SetGuard" + toIdent("", v.Ref.Source) + toIdent("", v.Ref.Target),
+				Call:   fmt.Sprintf("c.m.Var().Guard(string(%s), %s)", toIdent("Action", v.Ref.Source), toIdent("Cell", v.Ref.Target)),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := codegenTmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("codegen: render: %w", err)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: format: %w", err)
+	}
+	return out, nil
+}
+
+// toIdent turns a dsl label into an exported Go identifier, optionally
+// prefixed (Cell_P0, Action_INC0). Non-identifier runes are dropped,
+// except a leading one, which becomes '_'.
+func toIdent(prefix, label string) string {
+	var b strings.Builder
+	if prefix != "" {
+		b.WriteString(prefix)
+		b.WriteByte('_')
+	}
+	for i, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '_':
+			b.WriteRune(r)
+		default:
+			if i == 0 {
+				b.WriteByte('_')
+			}
+		}
+	}
+	return b.String()
+}
+
+var codegenTmpl = template.Must(template.New("codegen").Parse(`// Code generated by cmd/finitegen from schema {{.Schema}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/stackdump/finite-model/model/dsl"
+)
+
+// Cell constants mirror the places declared in schema {{.Schema}}.
+const (
+{{- range .Places}}
+	{{.Ident}} dsl.CellRef = "{{.Label}}"
+{{- end}}
+)
+
+// Action constants mirror the transitions declared in schema {{.Schema}}.
+const (
+{{- range .Transitions}}
+	{{.Ident}} dsl.Action = "{{.Label}}"
+{{- end}}
+)
+
+// Client wraps a dsl.Model, tracking the current StateVector across
+// transition calls so callers never thread state by hand.
+type Client struct {
+	m     dsl.Model
+	sm    *dsl.StateMachine
+	state dsl.StateVector
+}
+
+// NewClient wraps m. Call Build once all Set* binders have been applied.
+func NewClient(m dsl.Model) *Client {
+	return &Client{m: m}
+}
+
+// Build evaluates the Vars bound via the Set* methods below and
+// initializes the current StateVector from the resulting StateMachine.
+func (c *Client) Build() {
+	c.sm = c.m.StateMachine()
+	c.state = c.sm.Initial
+}
+
+// State returns the StateVector as of the most recent transition.
+func (c *Client) State() dsl.StateVector {
+	return c.state
+}
+{{range .Binders}}
+// {{.Method}} late-binds a Var declared by schema {{$.Schema}}.
+func (c *Client) {{.Method}}(fn func() uint64) {
+	{{.Call}}.Bind(fn)
+}
+{{end}}
+{{range .Transitions}}
+// {{.Method}} fires the "{{.Label}}" transition by times, evaluating any
+// Guards attached to it, and returns the resulting StateVector.
+func (c *Client) {{.Method}}(by uint64) (dsl.StateVector, error) {
+	if c.sm == nil {
+		return nil, fmt.Errorf("%s: Client.Build was never called", {{.Ident}})
+	}
+	out, _, err := c.m.Transform(c.state, {{.Ident}}, by)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", {{.Ident}}, err)
+	}
+	c.state = out
+	return out, nil
+}
+{{end}}
+`))
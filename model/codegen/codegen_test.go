@@ -0,0 +1,44 @@
+package codegen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackdump/finite-model/model/codegen"
+	. "github.com/stackdump/finite-model/model/dsl"
+)
+
+func modelDef(role RoleDef, cell CellDef, fn FnDef) {
+	userRole := role("default")
+	dec0 := fn("DEC0", Defun{Role: userRole})
+	p0 := cell("00", Cell{Initial: 0}).TX(1, dec0)
+	fn("INC0", Defun{Role: userRole}).TX(1, p0)
+}
+
+func TestGenerate(t *testing.T) {
+	m := NewModel("Counter", modelDef)
+	m.Var().Capacity("00")
+
+	a, err := m.Marshal()
+	assert.NoError(t, err)
+
+	mm, err := Unmarshal(a)
+	assert.NoError(t, err)
+
+	src, err := codegen.Generate("counter", mm)
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "package counter")
+	assert.Contains(t, string(src), "Cell_00")
+	assert.Contains(t, string(src), "Action_INC0")
+	assert.True(t, strings.Contains(string(src), "func (c *Client) INC0"))
+
+	// mm went through a Marshal/Unmarshal round trip, the same path
+	// cmd/finitegen uses: the binder must still be emitted.
+	assert.Contains(t, string(src), "func (c *Client) SetCapacity00")
+
+	// generated transitions must go through the guard-aware dsl.Model
+	// wrapper, not the bare statemachine.StateMachine.
+	assert.Contains(t, string(src), "c.m.Transform(c.state, Action_INC0, by)")
+}
@@ -0,0 +1,262 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Proposal is one actor's request to fire action by multiplier against
+// the same initial StateVector as every other Proposal in a Batch
+type Proposal struct {
+	Voter      Actor
+	Action     Action
+	Multiplier uint64
+}
+
+// ConflictPolicy controls how repeated votes from the same Voter within
+// a single conflict set are resolved
+type ConflictPolicy int
+
+const (
+	// KeepFirstVote keeps a voter's first Proposal in a conflict set (in
+	// submission order) and drops any later ones from the same voter
+	KeepFirstVote ConflictPolicy = 0
+
+	// RejectVoter drops every Proposal from a voter that submitted more
+	// than one conflicting Proposal in the same conflict set
+	RejectVoter ConflictPolicy = 1
+)
+
+// ErrConflicted is returned for a losing Proposal, referencing the
+// Proposal that won its conflict set
+type ErrConflicted struct {
+	Winner Proposal
+}
+
+func (e *ErrConflicted) Error() string {
+	return fmt.Sprintf("conflicts with winning proposal %s by %s", e.Winner.Action, e.Winner.Voter)
+}
+
+// Result is produced for every Proposal submitted to Batch.Resolve
+type Result struct {
+	Proposal Proposal
+	Out      StateVector
+	Err      error
+}
+
+// Batch resolves a set of Proposals against the same initial StateVector
+// into a conflict-free, deterministic firing schedule. Borrowing from
+// Avalanche-style consensus, a single voter must not be counted as
+// endorsing two mutually-conflicting proposals in the same poll
+type Batch struct {
+	Policy ConflictPolicy
+}
+
+// NewBatch constructs a Batch that resolves per-voter conflicts with policy
+func NewBatch(policy ConflictPolicy) *Batch {
+	return &Batch{Policy: policy}
+}
+
+// proposalNode carries the per-proposal working state used while
+// resolving a Batch
+type proposalNode struct {
+	idx      int
+	proposal Proposal
+	delta    []int64
+}
+
+// Resolve builds a conflict graph over proposals, dedupes per-voter
+// votes within each conflict set per b.Policy, greedily selects a
+// maximum-weight independent set (lowest-conflict-degree proposals
+// first, submission order breaking ties) within each set, and fires the
+// winners in that deterministic order against initial. Every Proposal in
+// proposals gets exactly one Result, in the same order.
+func (b *Batch) Resolve(m Model, initial StateVector, proposals []Proposal) []Result {
+	net := m.PTNet()
+	guards := m.Guards()
+
+	nodes := make([]proposalNode, len(proposals))
+	for i, p := range proposals {
+		t := net.Transitions[p.Action]
+		delta := make([]int64, len(t.Delta))
+		for j, d := range t.Delta {
+			delta[j] = d * int64(p.Multiplier)
+		}
+		nodes[i] = proposalNode{idx: i, proposal: p, delta: delta}
+	}
+
+	capacities := make(map[int]uint64, len(net.Places))
+	for _, pl := range net.Places {
+		capacities[pl.Offset] = pl.Capacity
+	}
+
+	conflicts := func(a, c proposalNode) bool {
+		for off := range a.delta {
+			sum := int64(initial[off]) + a.delta[off] + c.delta[off]
+			if sum < 0 {
+				return true
+			}
+			if capacity, ok := capacities[off]; ok && capacity > 0 && sum > int64(capacity) {
+				return true
+			}
+		}
+		for _, ga := range guards[a.proposal.Action] {
+			for _, gc := range guards[c.proposal.Action] {
+				if ga.Place != "" && ga.Place == gc.Place {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// union-find groups proposals into conflict sets
+	parent := make([]int, len(nodes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	union := func(x, y int) {
+		rx, ry := find(x), find(y)
+		if rx != ry {
+			parent[rx] = ry
+		}
+	}
+	for i := 0; i < len(nodes); i++ {
+		for j := i + 1; j < len(nodes); j++ {
+			if conflicts(nodes[i], nodes[j]) {
+				union(i, j)
+			}
+		}
+	}
+
+	sets := make(map[int][]int)
+	for i := range nodes {
+		r := find(i)
+		sets[r] = append(sets[r], i)
+	}
+	roots := make([]int, 0, len(sets))
+	for r := range sets {
+		roots = append(roots, r)
+	}
+	sort.Ints(roots)
+
+	results := make([]Result, len(proposals))
+	for _, r := range roots {
+		members := sets[r]
+		sort.Ints(members) // deterministic submission order within the set
+
+		eligible, supersededBy := b.dedupeVotes(nodes, members, results)
+
+		// greedily build a maximum-weight independent set: every eligible
+		// node carries exactly one vote (dedupeVotes already collapsed a
+		// voter's conflicting proposals down to at most one per set), so
+		// maximizing vote count means maximizing the set's cardinality.
+		// Processing lowest-conflict-degree proposals first is a standard
+		// heuristic for approximating a maximum independent set greedily;
+		// submission order only breaks ties, it no longer drives selection.
+		degree := make(map[int]int, len(eligible))
+		for _, i := range eligible {
+			for _, j := range eligible {
+				if i != j && conflicts(nodes[i], nodes[j]) {
+					degree[i]++
+				}
+			}
+		}
+		order := append([]int(nil), eligible...)
+		sort.SliceStable(order, func(a, c int) bool {
+			return degree[order[a]] < degree[order[c]]
+		})
+
+		beatenBy := make(map[int]int, len(order))
+		var winners []int
+		for _, i := range order {
+			beater := -1
+			for _, w := range winners {
+				if conflicts(nodes[i], nodes[w]) {
+					beater = w
+					break
+				}
+			}
+			if beater == -1 {
+				winners = append(winners, i)
+			} else {
+				beatenBy[i] = beater
+			}
+		}
+
+		// resolve every losing index (beaten or deduped) to the proposal
+		// that actually won its conflict set, so ErrConflicted.Winner
+		// never references a proposal that itself lost; a winner whose
+		// Transform returns an error is still the one referenced
+		var winnerOf func(int) int
+		winnerOf = func(i int) int {
+			if w, beaten := beatenBy[i]; beaten {
+				return winnerOf(w)
+			}
+			if s, deduped := supersededBy[i]; deduped {
+				return winnerOf(s)
+			}
+			return i
+		}
+
+		state := initial
+		for _, w := range winners {
+			out, _, err := m.Transform(state, nodes[w].proposal.Action, nodes[w].proposal.Multiplier)
+			if err != nil {
+				results[w] = Result{Proposal: nodes[w].proposal, Err: err}
+				continue
+			}
+			state = out
+			results[w] = Result{Proposal: nodes[w].proposal, Out: out}
+		}
+		for i := range beatenBy {
+			results[i] = Result{Proposal: nodes[i].proposal, Err: &ErrConflicted{Winner: nodes[winnerOf(i)].proposal}}
+		}
+		for i := range supersededBy {
+			results[i] = Result{Proposal: nodes[i].proposal, Err: &ErrConflicted{Winner: nodes[winnerOf(i)].proposal}}
+		}
+	}
+
+	return results
+}
+
+// dedupeVotes applies b.Policy to members (a single conflict set). Under
+// RejectVoter every proposal from a voter with more than one entry in
+// members is rejected immediately, since none of them can be credited as
+// that voter's vote. Otherwise a voter's later proposals are superseded
+// by its first (in members order); supersededBy maps a superseded index
+// to the index that stands in for its vote. It returns the indices (in
+// members order) that remain eligible to compete for a spot in the
+// independent set, and the supersededBy map described above
+func (b *Batch) dedupeVotes(nodes []proposalNode, members []int, results []Result) ([]int, map[int]int) {
+	voterCount := make(map[Actor]int)
+	for _, i := range members {
+		voterCount[nodes[i].proposal.Voter]++
+	}
+
+	voterFirst := make(map[Actor]int)
+	supersededBy := make(map[int]int)
+	var eligible []int
+	for _, i := range members {
+		voter := nodes[i].proposal.Voter
+		if b.Policy == RejectVoter && voterCount[voter] > 1 {
+			results[i] = Result{Proposal: nodes[i].proposal, Err: fmt.Errorf("voter %s rejected: multiple conflicting proposals in the same poll", voter)}
+			continue
+		}
+		if first, seen := voterFirst[voter]; seen {
+			supersededBy[i] = first
+			continue
+		}
+		voterFirst[voter] = i
+		eligible = append(eligible, i)
+	}
+
+	return eligible, supersededBy
+}
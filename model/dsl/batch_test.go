@@ -0,0 +1,109 @@
+package dsl_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/stackdump/finite-model/model/dsl"
+)
+
+// single place with capacity 1, fed by two competing Fns so that firing
+// both against the same initial StateVector would overflow it
+func seatModelDef(role RoleDef, cell CellDef, fn FnDef) {
+	userRole := role("default")
+
+	claimA := fn("CLAIM_A", Defun{Role: userRole})
+	claimB := fn("CLAIM_B", Defun{Role: userRole})
+
+	seat := cell("seat", Cell{Initial: 0, Capacity: 1})
+	claimA.TX(1, seat)
+	claimB.TX(1, seat)
+}
+
+// single place shaped so the conflict graph over {TAKE_A, TAKE_B, TAKE_C}
+// is a path: TAKE_A conflicts with TAKE_B (1+3>3), TAKE_B conflicts with
+// TAKE_C (3+1>3), but TAKE_A and TAKE_C do not (1+1<=3)
+func pathConflictModelDef(role RoleDef, cell CellDef, fn FnDef) {
+	userRole := role("default")
+	seat := cell("seat", Cell{Initial: 0, Capacity: 3})
+
+	fn("TAKE_A", Defun{Role: userRole}).TX(1, seat)
+	fn("TAKE_B", Defun{Role: userRole}).TX(3, seat)
+	fn("TAKE_C", Defun{Role: userRole}).TX(1, seat)
+}
+
+func TestBatchResolveDropsConflictingProposal(t *testing.T) {
+	m := NewModel("Seat", seatModelDef)
+	sm := m.StateMachine()
+
+	b := NewBatch(KeepFirstVote)
+	results := b.Resolve(m, sm.Initial, []Proposal{
+		{Voter: "alice", Action: "CLAIM_A", Multiplier: 1},
+		{Voter: "bob", Action: "CLAIM_B", Multiplier: 1},
+	})
+
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, StateVector{1}, results[0].Out)
+
+	assert.Error(t, results[1].Err)
+	var conflicted *ErrConflicted
+	assert.ErrorAs(t, results[1].Err, &conflicted)
+	assert.Equal(t, Action("CLAIM_A"), conflicted.Winner.Action)
+}
+
+func TestBatchResolveDedupesRepeatedVoter(t *testing.T) {
+	m := NewModel("Seat", seatModelDef)
+	sm := m.StateMachine()
+
+	b := NewBatch(KeepFirstVote)
+	results := b.Resolve(m, sm.Initial, []Proposal{
+		{Voter: "alice", Action: "CLAIM_A", Multiplier: 1},
+		{Voter: "alice", Action: "CLAIM_B", Multiplier: 1},
+	})
+
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+}
+
+// a voter rejected under RejectVoter must have every one of its proposals
+// rejected with the same error, never an ErrConflicted pointing at a
+// "winner" that is itself being rejected
+func TestBatchResolveRejectVoterRejectsAllDuplicates(t *testing.T) {
+	m := NewModel("Seat", seatModelDef)
+	sm := m.StateMachine()
+
+	b := NewBatch(RejectVoter)
+	results := b.Resolve(m, sm.Initial, []Proposal{
+		{Voter: "alice", Action: "CLAIM_A", Multiplier: 1},
+		{Voter: "alice", Action: "CLAIM_B", Multiplier: 1},
+	})
+
+	for _, r := range results {
+		assert.Error(t, r.Err)
+		var conflicted *ErrConflicted
+		assert.False(t, errors.As(r.Err, &conflicted), "rejected voter's proposal must not reference a winner")
+	}
+}
+
+// on a path-shaped conflict graph (A-B, B-C, A and C not conflicting)
+// submitted in degree-descending order, the resolver must pick the
+// larger independent set {A, C} over the single highest-degree node {B}
+func TestBatchResolvePicksMaximumIndependentSet(t *testing.T) {
+	m := NewModel("PathConflict", pathConflictModelDef)
+	sm := m.StateMachine()
+
+	b := NewBatch(KeepFirstVote)
+	results := b.Resolve(m, sm.Initial, []Proposal{
+		{Voter: "v1", Action: "TAKE_B", Multiplier: 1},
+		{Voter: "v2", Action: "TAKE_A", Multiplier: 1},
+		{Voter: "v3", Action: "TAKE_C", Multiplier: 1},
+	})
+
+	assert.Len(t, results, 3)
+	assert.Error(t, results[0].Err, "TAKE_B loses to the larger {TAKE_A, TAKE_C} independent set")
+	assert.NoError(t, results[1].Err)
+	assert.NoError(t, results[2].Err)
+}
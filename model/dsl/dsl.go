@@ -4,6 +4,8 @@ package dsl
 // for working with the finite MetaModel framework
 
 import (
+	"io"
+
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/stackdump/finite-model/model"
 	"github.com/stackdump/gopflow/ptnet"
@@ -22,6 +24,8 @@ type Model interface {
 	Var() Var
 	GetVars() []*model.VarMap
 	StateMachine() *StateMachine
+	Transform(current StateVector, action Action, multiplier uint64) (StateVector, Role, error)
+	Guards() map[Action][]GuardExpr
 	PTNet() *ptnet.PTNet
 	Marshal() (*any.Any, error)
 }
@@ -43,8 +47,15 @@ type Action = statemachine.Action
 // define a new place
 type CellDef func(CellRef, Cell) *model.Node
 
-// define a new transition
-type FnDef func(Fn, Defun) *model.Node
+// define a new transition, with optional Guards evaluated (in order)
+// against the current StateVector before the transition's Delta is
+// applied; a Guard compiled from an inhibitor arc is attached
+// automatically and does not need to be passed here
+type FnDef func(Fn, Defun, ...GuardExpr) *model.Node
+
+// GuardExpr is a boolean predicate attached to a transition, either
+// compiled from an inhibitor arc or supplied directly to FnDef
+type GuardExpr = model.Guard
 
 // define a new role
 type RoleDef func(Actor) Role
@@ -61,7 +72,7 @@ func NewModel(schema string, m ModelDeclaration) Model {
 	i := new(instance)
 	i.schema = schema
 	i.MetaModel = model.New(i.schema)
-	m(i.Role, i.Place, i.Transition) // re-index state machine
+	m(i.Role, i.Place, i.transition) // re-index state machine
 	return i
 }
 
@@ -83,6 +94,28 @@ func (i *instance) Var() model.Var {
 	return i.NewVar()
 }
 
+// transition adapts model.MetaModel.Transition to FnDef's signature,
+// attaching any passed Guards to the newly declared transition
+func (i *instance) transition(label Fn, d Defun, guards ...GuardExpr) *Node {
+	n := i.MetaModel.Transition(label, d)
+	for _, g := range guards {
+		i.MetaModel.AddGuard(Action(label), g)
+	}
+	return n
+}
+
+// Transform evaluates any Guards attached to action before delegating to
+// the underlying StateMachine.Transform
+func (i *instance) Transform(current StateVector, action Action, multiplier uint64) (StateVector, Role, error) {
+	sm := i.StateMachine()
+	return i.MetaModel.Transform(sm, current, action, multiplier)
+}
+
+// Guards exposes the Guards attached to each transition, keyed by Action
+func (i *instance) Guards() map[Action][]GuardExpr {
+	return i.MetaModel.Guards
+}
+
 // runtime error when assembling dsl vars
 func assertOK(ok bool) {
 	if !ok {
@@ -131,6 +164,20 @@ func (i *instance) StateMachine() *statemachine.StateMachine {
 			assertOK(ok)
 			p.Initial = v.GetVal()
 			net.Places[v.Target] = p // overwrite
+		case model.GuardVar:
+			_, ok = net.Transitions[Action(v.Source)]
+			assertOK(ok)
+			p, ok = net.Places[v.Target]
+			assertOK(ok)
+			offset, weight := p.Offset, v.GetVal()
+			for idx, g := range i.MetaModel.Guards[Action(v.Source)] {
+				if g.Place == v.Target {
+					i.MetaModel.Guards[Action(v.Source)][idx].Weight = weight
+					i.MetaModel.Guards[Action(v.Source)][idx].Fn = func(sv StateVector) bool {
+						return sv[offset] < weight
+					}
+				}
+			}
 		default:
 			panic("Unknown Type")
 		}
@@ -144,6 +191,11 @@ func Unmarshal(a *any.Any) (*model.MetaModel, error) {
 	return model.FromAny(a)
 }
 
+// load a model from a PNML Place/Transition net (see model.FromPNML)
+func FromPNML(r io.Reader) (*model.MetaModel, error) {
+	return model.FromPNML(r)
+}
+
 // TX Function pointer
 type Fn = model.Fn
 
@@ -2,6 +2,8 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/stackdump/gopflow/ptnet"
 	"github.com/stackdump/gopflow/statemachine"
@@ -104,14 +106,15 @@ type MetaModel struct {
 	Schema      string                                           `json:"schema"`
 	Places      map[string]*ptnet.Place                          `json:"places"`
 	Transitions map[statemachine.Action]*statemachine.Transition `json:"transitions"`
+	Guards      map[statemachine.Action][]Guard                  `json:"guards"`
 	VectorSize  int                                              `json:"-"`
-	vars        []*VarMap
+	Vars        []*VarMap                                        `json:"vars"`
 	arcs        []Arc
 	frozen      bool
 }
 
 func (m *MetaModel) GetVars() []*VarMap {
-	return m.vars
+	return m.Vars
 }
 
 func (m *MetaModel) IsFrozen() bool {
@@ -136,18 +139,70 @@ func (m *MetaModel) AppendArc(a Arc) {
 	m.arcs = append(m.arcs, a)
 }
 
+// GuardFunc evaluates a predicate over a StateVector before a transition
+// is applied; returning false blocks the Transform
+type GuardFunc func(statemachine.StateVector) bool
+
+// Guard is a named boolean predicate attached to a transition. Place and
+// Weight are populated for guards compiled from inhibitor arcs so a
+// late-bound threshold can find and replace their Fn (see VarMap.Guard),
+// and so Fn (unexported behavior, not data) can be rebuilt after a
+// ToAny/FromAny round trip
+type Guard struct {
+	Label  string
+	Place  string
+	Weight uint64
+	Fn     GuardFunc `json:"-"`
+}
+
+// AddGuard attaches g to action, evaluated before action's Delta is
+// applied by Transform
+func (m *MetaModel) AddGuard(action statemachine.Action, g Guard) {
+	m.assertNotFrozen()
+	m.Guards[action] = append(m.Guards[action], g)
+}
+
+// ErrGuardFailed is returned by Transform when a Guard attached to the
+// attempted action rejects the current StateVector
+type ErrGuardFailed struct {
+	Label string
+}
+
+func (e *ErrGuardFailed) Error() string {
+	return fmt.Sprintf("guard failed: %s", e.Label)
+}
+
+// Transform evaluates any Guards attached to action against current
+// before delegating to sm.Transform, so an inhibited transition fails
+// with ErrGuardFailed instead of applying its Delta
+func (m *MetaModel) Transform(sm *statemachine.StateMachine, current statemachine.StateVector, action statemachine.Action, multiplier uint64) (statemachine.StateVector, statemachine.Role, error) {
+	for _, g := range m.Guards[action] {
+		if g.Fn != nil && !g.Fn(current) {
+			return current, "", &ErrGuardFailed{Label: g.Label}
+		}
+	}
+
+	delta, role, err := sm.Transform(current, string(action), multiplier)
+	out := make(statemachine.StateVector, len(delta))
+	for i, v := range delta {
+		out[i] = uint64(v)
+	}
+	return out, role, err
+}
+
 func New(Schema string) *MetaModel {
 	m := new(MetaModel)
 	m.Schema = Schema
 	m.Places = make(map[string]*ptnet.Place)
 	m.Transitions = make(map[statemachine.Action]*statemachine.Transition)
-	m.vars = make([]*VarMap, 0)
+	m.Guards = make(map[statemachine.Action][]Guard)
+	m.Vars = make([]*VarMap, 0)
 	return m
 }
 
 func (m *MetaModel) NewVar() Var {
 	v := NewVar()
-	m.vars = append(m.vars, v.unpack())
+	m.Vars = append(m.Vars, v.unpack())
 	return v
 }
 
@@ -183,13 +238,33 @@ func (m *MetaModel) Role(label string) statemachine.Role {
 
 // re-indexes model and marks as frozen
 func (m *MetaModel) Freeze() *MetaModel {
+	if m.frozen {
+		return m
+	}
+
 	for k, t := range m.Transitions {
 		t.Delta = make([]int64, m.VectorSize)
 		m.Transitions[k] = t // overwrite
 	}
 
 	for _, a := range m.arcs {
-		// FIXME deal w/ inhibitors by converting to guards
+		if a.Type == INHIBITOR {
+			if !a.Source.IsPlace() || !a.Target.IsTransition() {
+				panic("bad arc declaration")
+			}
+			p := a.Source
+			t := a.Target
+			offset, weight := p.Offset, a.Weight
+			m.Guards[statemachine.Action(t.Label)] = append(m.Guards[statemachine.Action(t.Label)], Guard{
+				Label:  fmt.Sprintf("%s inhibits %s", p.Label, t.Label),
+				Place:  p.Label,
+				Weight: weight,
+				Fn: func(sv statemachine.StateVector) bool {
+					return sv[offset] < weight
+				},
+			})
+			continue
+		}
 		if a.Source.IsPlace() && a.Target.IsTransition() {
 			p := a.Source
 			t := a.Target
@@ -236,11 +311,38 @@ func (m *MetaModel) ToAny() (n *any.Any, err error) {
 func FromAny(n *any.Any) (m *MetaModel, err error) {
 	m = new(MetaModel)
 	m.frozen = true
-	err = json.Unmarshal(n.GetValue(), m)
+	if err = json.Unmarshal(n.GetValue(), m); err != nil {
+		return nil, err
+	}
+	m.rebindGuardFuncs()
 	n = new(any.Any)
 	return m, err
 }
 
+// rebindGuardFuncs restores the unexported Fn closure on every Guard
+// deserialized from JSON whose Place names a known place, using its
+// Place/Weight so a Guard compiled from an inhibitor arc still evaluates
+// after a ToAny/FromAny round trip. Other Guards keep a nil Fn, which
+// Transform skips
+func (m *MetaModel) rebindGuardFuncs() {
+	for action, guards := range m.Guards {
+		for i, g := range guards {
+			if g.Fn != nil {
+				continue
+			}
+			place, ok := m.Places[g.Place]
+			if !ok {
+				continue
+			}
+			offset, weight := place.Offset, g.Weight
+			guards[i].Fn = func(sv statemachine.StateVector) bool {
+				return sv[offset] < weight
+			}
+		}
+		m.Guards[action] = guards
+	}
+}
+
 // position on x/y grid for visualization
 type Coords struct {
 	X int
@@ -273,6 +375,9 @@ type Var interface {
 	// adjust multiple on arc
 	Weight(n ...string) Var
 
+	// late-bind the threshold of an inhibitor-derived Guard on fn over place
+	Guard(fn string, place string) Var
+
 	Bind(bindFunc binding)
 
 	// get underlying obj
@@ -284,45 +389,55 @@ type varType int
 const InitialVar varType = 0
 const WeightVar varType = 1
 const CapacityVar varType = 2
+const GuardVar varType = 3
 
 // map input vars to MetaModel
 type VarMap struct {
-	Var
+	Var `json:"-"`
 	Ref
 	Coords
 	Label       string
 	Offset      int
 	Description string
-	binding
-	varType
+	binding     `json:"-"`
+	Kind        varType
 }
 
 func (v *VarMap) Type() varType {
-	return v.varType
+	return v.Kind
 }
 
 // set max capacity
 func (v *VarMap) Capacity(t string) Var {
-	v.varType = CapacityVar
+	v.Kind = CapacityVar
 	v.Ref.Source = t
 	return v
 }
 
 // set initial input
 func (v *VarMap) Initial(t string) Var {
-	v.varType = InitialVar
+	v.Kind = InitialVar
 	v.Ref.Target = t
 	return v
 }
 
 // set a transacted value Cell -> Fn or Fn -> Cell
 func (v *VarMap) Weight(n ...string) Var {
-	v.varType = WeightVar
+	v.Kind = WeightVar
 	v.Ref.Source = n[0]
 	v.Ref.Target = n[1]
 	return v
 }
 
+// late-bind the threshold of an inhibitor-derived Guard attached to fn
+// over place
+func (v *VarMap) Guard(fn string, place string) Var {
+	v.Kind = GuardVar
+	v.Ref.Source = fn
+	v.Ref.Target = place
+	return v
+}
+
 // bind variable to a value producing function
 func (v *VarMap) GetVal() uint64 {
 	if v.binding == nil {
@@ -10,11 +10,15 @@ import (
 )
 
 // invariants
+//
+// INC0/DEC0/INC1/DEC1 are left untyped so the same constant can be
+// passed where a Fn is expected (declaring a transition) and where an
+// Action is expected (firing one) without an explicit conversion.
 const (
-	INC0 Fn = "INC0"
-	DEC0 Fn = "DEC0"
-	INC1 Fn = "INC1"
-	DEC1 Fn = "DEC1"
+	INC0 = "INC0"
+	DEC0 = "DEC0"
+	INC1 = "INC1"
+	DEC1 = "DEC1"
 
 	user Actor = "default"
 
@@ -66,3 +70,45 @@ func TestModelBinding(t *testing.T) {
 	assert.Equal(t, statemachine.Role("default"), role)
 	assert.Equal(t, vout, []int64{7, 1})
 }
+
+// model with an inhibitor arc: DEC0 is blocked while p1 holds tokens
+func inhibitedModelDef(role RoleDef, cell CellDef, fn FnDef) {
+	userRole := role(user)
+
+	dec0 := fn(DEC0, Defun{Role: userRole})
+
+	p00 := cell(p0, Cell{Initial: 1}).TX(1, dec0)
+	cell(p1, Cell{Initial: 1}).Inhibitor(1, dec0)
+
+	fn(INC0, Defun{Role: userRole}).TX(1, p00)
+}
+
+func TestInhibitorArcCompilesToGuard(t *testing.T) {
+	m := NewModel("Inhibited", inhibitedModelDef)
+	sm := m.StateMachine()
+
+	out, role, err := m.Transform(sm.Initial, DEC0, 1)
+	assert.Equal(t, sm.Initial, out)
+	assert.Equal(t, statemachine.Role(""), role)
+	assert.EqualError(t, err, "guard failed: 01 inhibits DEC0")
+}
+
+// an inhibitor-derived Guard must still evaluate after a model goes
+// through a Marshal/Unmarshal round trip, even though its Fn closure is
+// unexported and never itself survives JSON
+func TestInhibitorArcGuardSurvivesRoundTrip(t *testing.T) {
+	m := NewModel("Inhibited", inhibitedModelDef)
+	sm := m.StateMachine()
+
+	a, err := m.Marshal()
+	assert.NoError(t, err)
+
+	mm, err := Unmarshal(a)
+	assert.NoError(t, err)
+	assert.Len(t, mm.Guards[DEC0], 1)
+
+	out, role, err := mm.Transform(sm, sm.Initial, DEC0, 1)
+	assert.Equal(t, sm.Initial, out)
+	assert.Equal(t, statemachine.Role(""), role)
+	assert.EqualError(t, err, "guard failed: 01 inhibits DEC0")
+}
@@ -0,0 +1,329 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/stackdump/gopflow/ptnet"
+	"github.com/stackdump/gopflow/statemachine"
+)
+
+// pnmlPTNetType identifies a Place/Transition net per ISO/IEC 15909-2
+const pnmlPTNetType = "http://www.pnml.org/version-2009/grammar/ptnet"
+
+// pnmlTool names the toolspecific block this package reads and writes
+const pnmlTool = "finite-model"
+
+type pnmlDocument struct {
+	XMLName xml.Name `xml:"pnml"`
+	Net     pnmlNet  `xml:"net"`
+}
+
+type pnmlNet struct {
+	ID     string            `xml:"id,attr"`
+	Type   string            `xml:"type,attr"`
+	Places []pnmlPlace       `xml:"place"`
+	Trans  []pnmlTransition  `xml:"transition"`
+	Arcs   []pnmlArc         `xml:"arc"`
+	Tool   *pnmlToolSpecific `xml:"toolspecific,omitempty"`
+}
+
+type pnmlName struct {
+	Text string `xml:"text"`
+}
+
+type pnmlText struct {
+	Text string `xml:"text"`
+}
+
+type pnmlGraphics struct {
+	Position pnmlPosition `xml:"position"`
+}
+
+type pnmlPosition struct {
+	X int `xml:"x,attr"`
+	Y int `xml:"y,attr"`
+}
+
+type pnmlPlace struct {
+	ID             string        `xml:"id,attr"`
+	Name           pnmlName      `xml:"name"`
+	InitialMarking *pnmlText     `xml:"initialMarking,omitempty"`
+	Capacity       *pnmlText     `xml:"capacity,omitempty"`
+	Graphics       *pnmlGraphics `xml:"graphics,omitempty"`
+}
+
+type pnmlTransition struct {
+	ID       string            `xml:"id,attr"`
+	Name     pnmlName          `xml:"name"`
+	Graphics *pnmlGraphics     `xml:"graphics,omitempty"`
+	Tool     *pnmlToolSpecific `xml:"toolspecific,omitempty"`
+}
+
+type pnmlArcType struct {
+	Value string `xml:"value,attr"`
+}
+
+type pnmlArc struct {
+	ID          string       `xml:"id,attr"`
+	Source      string       `xml:"source,attr"`
+	Target      string       `xml:"target,attr"`
+	Type        *pnmlArcType `xml:"type,omitempty"`
+	Inscription *pnmlText    `xml:"inscription,omitempty"`
+}
+
+// pnmlToolSpecific carries finite-model data with no standard PNML
+// equivalent: a transition's Role, and unbound VarMap placeholders
+type pnmlToolSpecific struct {
+	Tool    string       `xml:"tool,attr"`
+	Version string       `xml:"version,attr"`
+	Role    string       `xml:"role,attr,omitempty"`
+	Vars    []pnmlVarRef `xml:"var,omitempty"`
+}
+
+type pnmlVarRef struct {
+	Type   string `xml:"type,attr"`
+	Source string `xml:"source,attr,omitempty"`
+	Target string `xml:"target,attr,omitempty"`
+}
+
+var varTypeNames = map[varType]string{
+	InitialVar:  "initial",
+	WeightVar:   "weight",
+	CapacityVar: "capacity",
+	GuardVar:    "guard",
+}
+
+var varTypeByName = map[string]varType{
+	"initial":  InitialVar,
+	"weight":   WeightVar,
+	"capacity": CapacityVar,
+	"guard":    GuardVar,
+}
+
+// ToPNML writes m as a PNML Place/Transition net (ISO/IEC 15909-2),
+// preserving Role and Var bindings in a tool-specific block so a model
+// authored in the Go DSL can round-trip through standard Petri-net
+// editors (CPN Tools, WoPeD, Snoopy) via FromPNML
+func (m *MetaModel) ToPNML(w io.Writer) error {
+	if !m.IsFrozen() {
+		m.Freeze()
+	}
+
+	coords := map[string]Coords{}
+	for _, v := range m.Vars {
+		if v.Coords.X == 0 && v.Coords.Y == 0 {
+			continue
+		}
+		if v.Ref.Source != "" {
+			coords[v.Ref.Source] = v.Coords
+		}
+		if v.Ref.Target != "" {
+			coords[v.Ref.Target] = v.Coords
+		}
+	}
+
+	doc := pnmlDocument{Net: pnmlNet{ID: m.Schema, Type: pnmlPTNetType}}
+
+	placeLabels := make([]string, 0, len(m.Places))
+	for label := range m.Places {
+		placeLabels = append(placeLabels, label)
+	}
+	// places are written in Offset order, since FromPNML assigns offsets
+	// in document order
+	sort.Slice(placeLabels, func(i, j int) bool {
+		return m.Places[placeLabels[i]].Offset < m.Places[placeLabels[j]].Offset
+	})
+	for _, label := range placeLabels {
+		p := m.Places[label]
+		doc.Net.Places = append(doc.Net.Places, pnmlPlace{
+			ID:             label,
+			Name:           pnmlName{Text: label},
+			InitialMarking: &pnmlText{Text: strconv.FormatUint(p.Initial, 10)},
+			Capacity:       &pnmlText{Text: strconv.FormatUint(p.Capacity, 10)},
+			Graphics:       pnmlGraphicsFor(coords, label),
+		})
+	}
+
+	txLabels := make([]string, 0, len(m.Transitions))
+	for action := range m.Transitions {
+		txLabels = append(txLabels, string(action))
+	}
+	sort.Strings(txLabels)
+	for _, label := range txLabels {
+		t := m.Transitions[statemachine.Action(label)]
+		tx := pnmlTransition{ID: label, Name: pnmlName{Text: label}, Graphics: pnmlGraphicsFor(coords, label)}
+		if t.Role != "" {
+			tx.Tool = &pnmlToolSpecific{Tool: pnmlTool, Role: string(t.Role)}
+		}
+		doc.Net.Trans = append(doc.Net.Trans, tx)
+	}
+
+	// arcs are derived from each Transition's Delta and from Guards that
+	// name a Place (both serialized, unlike the private arcs slice built at DSL
+	// declaration time) so ToPNML also works on a model that has gone
+	// through a ToAny/FromAny round trip
+	i := 0
+	for _, label := range txLabels {
+		t := m.Transitions[statemachine.Action(label)]
+		for _, place := range placeLabels {
+			delta := t.Delta[m.Places[place].Offset]
+			if delta == 0 {
+				continue
+			}
+			arc := pnmlArc{ID: fmt.Sprintf("arc%d", i)}
+			if delta < 0 {
+				arc.Source, arc.Target = place, label
+				arc.Inscription = &pnmlText{Text: strconv.FormatUint(uint64(-delta), 10)}
+			} else {
+				arc.Source, arc.Target = label, place
+				arc.Inscription = &pnmlText{Text: strconv.FormatUint(uint64(delta), 10)}
+			}
+			doc.Net.Arcs = append(doc.Net.Arcs, arc)
+			i++
+		}
+		for _, g := range m.Guards[statemachine.Action(label)] {
+			if g.Place == "" {
+				continue
+			}
+			doc.Net.Arcs = append(doc.Net.Arcs, pnmlArc{
+				ID:          fmt.Sprintf("arc%d", i),
+				Source:      g.Place,
+				Target:      label,
+				Type:        &pnmlArcType{Value: "inhibitor"},
+				Inscription: &pnmlText{Text: strconv.FormatUint(g.Weight, 10)},
+			})
+			i++
+		}
+	}
+
+	if len(m.Vars) > 0 {
+		tool := &pnmlToolSpecific{Tool: pnmlTool}
+		for _, v := range m.Vars {
+			tool.Vars = append(tool.Vars, pnmlVarRef{
+				Type:   varTypeNames[v.Type()],
+				Source: v.Ref.Source,
+				Target: v.Ref.Target,
+			})
+		}
+		doc.Net.Tool = tool
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+func pnmlGraphicsFor(coords map[string]Coords, label string) *pnmlGraphics {
+	c, ok := coords[label]
+	if !ok {
+		return nil
+	}
+	return &pnmlGraphics{Position: pnmlPosition{X: c.X, Y: c.Y}}
+}
+
+// FromPNML reads a PNML Place/Transition net written by ToPNML (or an
+// equivalent P/T net from a standard tool) and reconstructs a frozen
+// MetaModel. Inhibitor arcs (<arc><type value="inhibitor"/></arc>)
+// compile directly to Guards, same as inhibitor arcs declared in the DSL
+func FromPNML(r io.Reader) (*MetaModel, error) {
+	var doc pnmlDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	m := New(doc.Net.ID)
+
+	offsets := make(map[string]int, len(doc.Net.Places))
+	for i, p := range doc.Net.Places {
+		initial, err := parsePNMLUint(p.InitialMarking)
+		if err != nil {
+			return nil, fmt.Errorf("pnml: place %s: initialMarking: %w", p.ID, err)
+		}
+		capacity, err := parsePNMLUint(p.Capacity)
+		if err != nil {
+			return nil, fmt.Errorf("pnml: place %s: capacity: %w", p.ID, err)
+		}
+		m.Places[p.ID] = &ptnet.Place{Offset: i, Initial: initial, Capacity: capacity}
+		offsets[p.ID] = i
+	}
+	m.VectorSize = len(doc.Net.Places)
+
+	for _, t := range doc.Net.Trans {
+		var role statemachine.Role
+		if t.Tool != nil {
+			role = statemachine.Role(t.Tool.Role)
+		}
+		m.Transitions[statemachine.Action(t.ID)] = &statemachine.Transition{
+			Role:  role,
+			Delta: make([]int64, m.VectorSize),
+		}
+	}
+
+	for _, a := range doc.Net.Arcs {
+		weight, err := parsePNMLUint(a.Inscription)
+		if err != nil {
+			return nil, fmt.Errorf("pnml: arc %s: inscription: %w", a.ID, err)
+		}
+		inhibitor := a.Type != nil && a.Type.Value == "inhibitor"
+
+		if offset, ok := offsets[a.Source]; ok {
+			t, ok := m.Transitions[statemachine.Action(a.Target)]
+			if !ok {
+				return nil, fmt.Errorf("pnml: arc %s: unknown transition %s", a.ID, a.Target)
+			}
+			if inhibitor {
+				place, target, off, threshold := a.Source, a.Target, offset, weight
+				m.Guards[statemachine.Action(target)] = append(m.Guards[statemachine.Action(target)], Guard{
+					Label:  fmt.Sprintf("%s inhibits %s", place, target),
+					Place:  place,
+					Weight: threshold,
+					Fn: func(sv statemachine.StateVector) bool {
+						return sv[off] < threshold
+					},
+				})
+			} else {
+				t.Delta[offset] = 0 - int64(weight)
+			}
+			continue
+		}
+		if offset, ok := offsets[a.Target]; ok {
+			t, ok := m.Transitions[statemachine.Action(a.Source)]
+			if !ok {
+				return nil, fmt.Errorf("pnml: arc %s: unknown transition %s", a.ID, a.Source)
+			}
+			t.Delta[offset] = int64(weight)
+			continue
+		}
+		return nil, fmt.Errorf("pnml: arc %s: neither endpoint is a known place", a.ID)
+	}
+
+	if doc.Net.Tool != nil {
+		for _, ref := range doc.Net.Tool.Vars {
+			vt, ok := varTypeByName[ref.Type]
+			if !ok {
+				return nil, fmt.Errorf("pnml: var: unknown type %q", ref.Type)
+			}
+			m.Vars = append(m.Vars, &VarMap{
+				Kind: vt,
+				Ref:  Ref{Source: ref.Source, Target: ref.Target},
+			})
+		}
+	}
+
+	m.frozen = true
+	return m, nil
+}
+
+func parsePNMLUint(t *pnmlText) (uint64, error) {
+	if t == nil || t.Text == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(t.Text, 10, 64)
+}
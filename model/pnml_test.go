@@ -0,0 +1,36 @@
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackdump/gopflow/statemachine"
+
+	. "github.com/stackdump/finite-model/model/dsl"
+)
+
+func TestPNMLRoundTrip(t *testing.T) {
+	m := NewModel("Inhibited", inhibitedModelDef)
+	sm := m.StateMachine()
+
+	var buf bytes.Buffer
+	a, err := m.Marshal()
+	assert.NoError(t, err)
+
+	mm, err := Unmarshal(a)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mm.ToPNML(&buf))
+	assert.Contains(t, buf.String(), `type="http://www.pnml.org/version-2009/grammar/ptnet"`)
+	assert.Contains(t, buf.String(), `value="inhibitor"`)
+
+	out, err := FromPNML(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "Inhibited", out.Schema)
+	assert.Equal(t, mm.Places["00"].Initial, out.Places["00"].Initial)
+	assert.Equal(t, mm.Places["01"].Initial, out.Places["01"].Initial)
+	assert.Len(t, out.Guards[statemachine.Action(DEC0)], 1)
+	assert.Equal(t, sm.Initial, StateVector{1, 1})
+}